@@ -0,0 +1,186 @@
+// Package admission implements a Kubernetes ValidatingAdmissionWebhook that
+// rejects DNSEndpoint objects external-dns would otherwise hand to the
+// Porkbun provider and fail on, or that would conflict with records already
+// under management.
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	porkbun "github.com/kjaleshire/external-dns-porkbun-webhook/provider"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/external-dns/endpoint"
+	extdnsprovider "sigs.k8s.io/external-dns/provider"
+)
+
+// unsupportedRecordTypes lists endpoint.RecordType values Porkbun's API
+// cannot serve as plain DNS records; URL/URLFWD are Porkbun URL forwards,
+// not records external-dns can create through the DNS API.
+var unsupportedRecordTypes = map[string]bool{
+	"URL":    true,
+	"URLFWD": true,
+}
+
+var codecs = serializer.NewCodecFactory(runtime.NewScheme())
+
+// Validator decides whether incoming DNSEndpoint CREATE/UPDATE requests are
+// safe to admit, using the live provider state as the source of truth.
+type Validator struct {
+	Provider     extdnsprovider.Provider
+	DomainFilter endpoint.DomainFilterInterface
+	Logger       *slog.Logger
+}
+
+// Handler returns an http.HandlerFunc serving AdmissionReview requests.
+func (v *Validator) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		requestLogger := v.Logger.With("method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+		ctx := porkbun.ContextWithLogger(r.Context(), requestLogger)
+
+		review := admissionv1.AdmissionReview{}
+		if _, _, err := codecs.UniversalDeserializer().Decode(body, nil, &review); err != nil {
+			requestLogger.Error("failed to decode admission review", "error", err)
+			http.Error(w, fmt.Sprintf("failed to decode admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := v.review(ctx, review.Request)
+		review.Response = response
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			v.Logger.Error("failed to encode admission review response", "error", err)
+		}
+	}
+}
+
+func (v *Validator) review(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+
+	var ep endpoint.DNSEndpoint
+	if _, _, err := codecs.UniversalDeserializer().Decode(req.Object.Raw, nil, &ep); err != nil {
+		return deny(req.UID, fmt.Sprintf("could not decode DNSEndpoint: %v", err))
+	}
+
+	existing, err := v.Provider.Records(ctx)
+	if err != nil {
+		v.Logger.Error("failed to list existing records for admission check", "error", err)
+		return deny(req.UID, fmt.Sprintf("could not verify record state: %v", err))
+	}
+
+	owned := ownedNameTypes(req)
+
+	for _, e := range ep.Spec.Endpoints {
+		if reason, ok := v.validateEndpoint(e, existing, owned); !ok {
+			return deny(req.UID, reason)
+		}
+	}
+
+	return resp
+}
+
+// nameType identifies a record by the pair Porkbun scopes it by; Porkbun has
+// no notion of per-identifier multi-value records, so this pair is the unit
+// of "the same record" for collision purposes.
+type nameType struct {
+	dnsName    string
+	recordType string
+}
+
+// ownedNameTypes returns the name/type pairs the DNSEndpoint being admitted
+// already owned before this request, so validateEndpoint can tell "this
+// resource's own record is being updated" apart from "a different resource
+// already has this name/type pinned." On CREATE, or if req.OldObject can't
+// be decoded, it returns an empty set, since there is no prior state to
+// exempt.
+func ownedNameTypes(req *admissionv1.AdmissionRequest) map[nameType]bool {
+	owned := map[nameType]bool{}
+	if req.Operation != admissionv1.Update || len(req.OldObject.Raw) == 0 {
+		return owned
+	}
+
+	var old endpoint.DNSEndpoint
+	if _, _, err := codecs.UniversalDeserializer().Decode(req.OldObject.Raw, nil, &old); err != nil {
+		return owned
+	}
+
+	for _, e := range old.Spec.Endpoints {
+		owned[nameType{e.DNSName, e.RecordType}] = true
+	}
+
+	return owned
+}
+
+// validateEndpoint checks a single endpoint against the domain filter,
+// Porkbun's supported record types, and existing pinned records. owned
+// excludes name/type pairs this same DNSEndpoint resource already owned
+// before the request, so a routine update to its own record isn't flagged
+// as a collision with itself.
+func (v *Validator) validateEndpoint(e *endpoint.Endpoint, existing []*endpoint.Endpoint, owned map[nameType]bool) (string, bool) {
+	if !v.DomainFilter.Match(e.DNSName) {
+		return fmt.Sprintf("%s targets a zone outside the configured domain filter", e.DNSName), false
+	}
+
+	if unsupportedRecordTypes[strings.ToUpper(e.RecordType)] {
+		return fmt.Sprintf("%s uses record type %s, which Porkbun's DNS API does not support", e.DNSName, e.RecordType), false
+	}
+
+	for _, have := range existing {
+		if have.DNSName != e.DNSName || have.RecordType != e.RecordType {
+			continue
+		}
+		if owned[nameType{have.DNSName, have.RecordType}] {
+			continue
+		}
+		if sameTargets(have.Targets, e.Targets) {
+			continue
+		}
+		return fmt.Sprintf("%s/%s already has a record pinned with different content; Porkbun does not support multiple values per name/type", e.DNSName, e.RecordType), false
+	}
+
+	return "", true
+}
+
+// sameTargets reports whether a and b hold the same record content.
+// Porkbun has no notion of per-identifier multi-value records, so any
+// existing endpoint for the same name/type is only compatible with an
+// incoming one if it is the same managed record.
+func sameTargets(a, b endpoint.Targets) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func deny(uid types.UID, reason string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		UID:     uid,
+		Allowed: false,
+		Result: &metav1.Status{
+			Message: reason,
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	extdnsprovider "sigs.k8s.io/external-dns/provider"
+)
+
+// fakeProvider is a minimal provider.Provider double whose Records() returns
+// a fixed set of existing endpoints.
+type fakeProvider struct {
+	extdnsprovider.BaseProvider
+
+	records []*endpoint.Endpoint
+}
+
+func (p *fakeProvider) GetDomainFilter() endpoint.DomainFilterInterface {
+	return endpoint.NewDomainFilter(nil)
+}
+
+func (p *fakeProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return p.records, nil
+}
+
+func (p *fakeProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return nil
+}
+
+func newTestValidator(existing ...*endpoint.Endpoint) *Validator {
+	return &Validator{
+		Provider:     &fakeProvider{records: existing},
+		DomainFilter: endpoint.NewDomainFilter([]string{"example.com"}),
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func marshalDNSEndpoint(t *testing.T, eps ...*endpoint.Endpoint) []byte {
+	t.Helper()
+	raw, err := json.Marshal(&endpoint.DNSEndpoint{Spec: endpoint.DNSEndpointSpec{Endpoints: eps}})
+	if err != nil {
+		t.Fatalf("marshaling DNSEndpoint: %v", err)
+	}
+	return raw
+}
+
+func TestValidator_validateEndpoint_domainFilter(t *testing.T) {
+	v := newTestValidator()
+
+	e := endpoint.NewEndpoint("foo.example.org", "A", "1.2.3.4")
+	if _, ok := v.validateEndpoint(e, nil, nil); ok {
+		t.Fatal("expected an endpoint outside the domain filter to be denied")
+	}
+}
+
+func TestValidator_validateEndpoint_unsupportedRecordType(t *testing.T) {
+	v := newTestValidator()
+
+	e := endpoint.NewEndpoint("foo.example.com", "URL", "https://example.org")
+	if _, ok := v.validateEndpoint(e, nil, nil); ok {
+		t.Fatal("expected a URL record to be denied")
+	}
+}
+
+func TestValidator_validateEndpoint_duplicateBareRecordsDenied(t *testing.T) {
+	v := newTestValidator()
+
+	have := endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4")
+	incoming := endpoint.NewEndpoint("foo.example.com", "A", "5.6.7.8")
+
+	if _, ok := v.validateEndpoint(incoming, []*endpoint.Endpoint{have}, nil); ok {
+		t.Fatal("expected a second bare record for the same name/type to be denied")
+	}
+}
+
+func TestValidator_validateEndpoint_sameManagedRecordAllowed(t *testing.T) {
+	v := newTestValidator()
+
+	have := endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4")
+	incoming := endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4")
+
+	if _, ok := v.validateEndpoint(incoming, []*endpoint.Endpoint{have}, nil); !ok {
+		t.Fatal("expected re-submitting the same record to be allowed")
+	}
+}
+
+func TestValidator_validateEndpoint_distinctNamesAllowed(t *testing.T) {
+	v := newTestValidator()
+
+	have := endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4")
+	incoming := endpoint.NewEndpoint("bar.example.com", "A", "5.6.7.8")
+
+	if _, ok := v.validateEndpoint(incoming, []*endpoint.Endpoint{have}, nil); !ok {
+		t.Fatal("expected an endpoint for a different name to be allowed")
+	}
+}
+
+func TestValidator_validateEndpoint_ownedNameTypeChangingTargetAllowed(t *testing.T) {
+	v := newTestValidator()
+
+	have := endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4")
+	incoming := endpoint.NewEndpoint("foo.example.com", "A", "5.6.7.8")
+	owned := map[nameType]bool{{dnsName: "foo.example.com", recordType: "A"}: true}
+
+	if _, ok := v.validateEndpoint(incoming, []*endpoint.Endpoint{have}, owned); !ok {
+		t.Fatal("expected an update to a name/type this resource already owns to be allowed even though the target changed")
+	}
+}
+
+func TestValidator_review_updateChangingTargetAllowed(t *testing.T) {
+	v := newTestValidator(endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4"))
+
+	req := &admissionv1.AdmissionRequest{
+		UID:       "test-uid",
+		Operation: admissionv1.Update,
+		Object:    runtime.RawExtension{Raw: marshalDNSEndpoint(t, endpoint.NewEndpoint("foo.example.com", "A", "5.6.7.8"))},
+		OldObject: runtime.RawExtension{Raw: marshalDNSEndpoint(t, endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4"))},
+	}
+
+	resp := v.review(context.Background(), req)
+	if !resp.Allowed {
+		t.Fatalf("expected an update changing only the target to be allowed, got denied: %s", resp.Result.Message)
+	}
+}
+
+func TestValidator_review_createCollidingWithOtherResourceDenied(t *testing.T) {
+	v := newTestValidator(endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4"))
+
+	req := &admissionv1.AdmissionRequest{
+		UID:       "test-uid",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: marshalDNSEndpoint(t, endpoint.NewEndpoint("foo.example.com", "A", "5.6.7.8"))},
+	}
+
+	resp := v.review(context.Background(), req)
+	if resp.Allowed {
+		t.Fatal("expected a create colliding with another resource's pinned record to be denied")
+	}
+}
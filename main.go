@@ -1,35 +1,50 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
-	"github.com/go-kit/log"
-	"github.com/go-kit/log/level"
+	gokitlog "github.com/go-kit/log"
+	"github.com/kjaleshire/external-dns-porkbun-webhook/admission"
 	porkbun "github.com/kjaleshire/external-dns-porkbun-webhook/provider"
 	"github.com/oklog/run"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/version"
 	"github.com/prometheus/exporter-toolkit/web"
+	"golang.org/x/crypto/bcrypt"
+	extdnsprovider "sigs.k8s.io/external-dns/provider"
 	webhook "sigs.k8s.io/external-dns/provider/webhook/api"
 )
 
 var (
-	logFormat         = kingpin.Flag("log-format", "The format in which log messages are printed (default: text, options: logfmt, json)").Default("logfmt").Envar("PORKBUN_LOG_FORMAT").String()
-	logLevel          = kingpin.Flag("log-level", "Set the level of logging. (default: info, options: panic, debug, info, warning, error, fatal)").Default("info").Envar("PORKBUN_LOG_LEVEL").String()
+	logFormat         = kingpin.Flag("log-format", "The format in which log messages are printed (default: text, options: text, json)").Default("text").Envar("PORKBUN_LOG_FORMAT").String()
+	logLevel          = kingpin.Flag("log-level", "Set the level of logging. (default: info, options: debug, info, warning, error)").Default("info").Envar("PORKBUN_LOG_LEVEL").String()
 	listenAddr        = kingpin.Flag("listen-address", "The address this plugin listens on").Default(":8888").Envar("PORKBUN_LISTEN_ADDRESS").String()
 	metricsListenAddr = kingpin.Flag("metrics-listen-address", "The address this plugin provides metrics on").Default(":8889").Envar("PORKBUN_METRICS_LISTEN_ADDRESS").String()
 	tlsConfig         = kingpin.Flag("tls-config", "Path to TLS config file.").Envar("PORKBUN_TLS_CONFIG").Default("").String()
 
-	domainFilter = kingpin.Flag("domain-filter", "Limit possible target zones by a domain suffix; specify multiple times for multiple domains").Required().Envar("PORKBUN_DOMAIN_FILTER").Strings()
+	domainFilter = kingpin.Flag("domain-filter", "Limit possible target zones by a domain suffix; specify multiple times for multiple domains").Envar("PORKBUN_DOMAIN_FILTER").Strings()
 	dryRun       = kingpin.Flag("dry-run", "Run without connecting to Porkbun's API").Default("false").Envar("PORKBUN_DRY_RUN").Bool()
-	apiKey       = kingpin.Flag("porkbun-api-key", "The API key to connect to Porkbun's API").Required().Envar("PORKBUN_API_KEY").String()
-	apiSecret    = kingpin.Flag("porkbun-secret-key", "The API secret to connect to Porkbun's API").Required().Envar("PORKBUN_SECRET_KEY").String()
+	apiKey       = kingpin.Flag("porkbun-api-key", "The API key to connect to Porkbun's API").Envar("PORKBUN_API_KEY").String()
+	apiSecret    = kingpin.Flag("porkbun-secret-key", "The API secret to connect to Porkbun's API").Envar("PORKBUN_SECRET_KEY").String()
+
+	providersConfig = kingpin.Flag("providers-config", "Path to a YAML/JSON file describing multiple upstream providers to aggregate; when set, domain-filter/porkbun-api-key/porkbun-secret-key are ignored").Envar("PORKBUN_PROVIDERS_CONFIG").String()
+
+	admissionListenAddr = kingpin.Flag("admission-listen-address", "The address this plugin serves a ValidatingAdmissionWebhook on; leave unset to disable admission checking").Default("").Envar("PORKBUN_ADMISSION_LISTEN_ADDRESS").String()
+
+	webhookAuthUser         = kingpin.Flag("webhook-auth-user", "Username required to authenticate to the webhook server; leave unset to disable auth").Default("").Envar("PORKBUN_WEBHOOK_AUTH_USER").String()
+	webhookAuthPasswordFile = kingpin.Flag("webhook-auth-password-file", "Path to a file holding a bcrypt hash of the password required to authenticate to the webhook server").Default("").Envar("PORKBUN_WEBHOOK_AUTH_PASSWORD_FILE").String()
+	metricsAuthUser         = kingpin.Flag("metrics-auth-user", "Username required to authenticate to the metrics server; leave unset to disable auth").Default("").Envar("PORKBUN_METRICS_AUTH_USER").String()
+	metricsAuthPasswordFile = kingpin.Flag("metrics-auth-password-file", "Path to a file holding a bcrypt hash of the password required to authenticate to the metrics server").Default("").Envar("PORKBUN_METRICS_AUTH_PASSWORD_FILE").String()
 )
 
 func main() {
@@ -37,24 +52,28 @@ func main() {
 	kingpin.Version(version.Info())
 	kingpin.Parse()
 
-	var logger log.Logger
+	handlerOpts := &slog.HandlerOptions{Level: parseLogLevel(*logLevel)}
+	var handler slog.Handler
 	switch *logFormat {
 	case "json":
-		logger = log.NewJSONLogger(log.NewSyncWriter(os.Stderr))
-	case "logfmt":
-		logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+		handler = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
 	default:
 		fmt.Printf("Error: Unknown log format: %s\n", *logFormat)
 		os.Exit(1)
 	}
-	logger = level.NewFilter(logger, level.Allow(level.ParseDefault(*logLevel, level.InfoValue())))
-	logger = log.With(logger, "ts", log.DefaultTimestampUTC, "caller", log.DefaultCaller)
-	_ = level.Info(logger).Log("msg", "starting external-dns Porkbun webhook plugin", "version", version.Version, "revision", version.Revision)
-	_ = level.Debug(logger).Log("api-key", *apiKey, "api-secret", *apiSecret)
+	logger := slog.New(newDedupHandler(handler))
+	logger.Info("starting external-dns Porkbun webhook plugin", "version", version.Version, "revision", version.Revision)
+	logger.Debug("loaded porkbun credentials", "api-key", *apiKey, "api-secret", *apiSecret)
 
 	prometheus.DefaultRegisterer.MustRegister(version.NewCollector("external_dns_porkbun"))
 
-	metricsMux := buildMetricsServer(prometheus.DefaultGatherer, logger)
+	metricsMux, err := buildMetricsServer(prometheus.DefaultGatherer, logger)
+	if err != nil {
+		logger.Error("Failed to create metrics server", "error", err)
+		os.Exit(1)
+	}
 	metricsServer := http.Server{
 		Handler:           metricsMux,
 		ReadHeaderTimeout: 5 * time.Second}
@@ -65,11 +84,13 @@ func main() {
 		WebConfigFile:      tlsConfig,
 	}
 
-	webhookMux, err := buildWebhookServer(logger)
+	webhookMux, ncProvider, err := buildWebhookServer(logger)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "Failed to create provider", "error", err)
+		logger.Error("Failed to create provider", "error", err)
 		os.Exit(1)
 	}
+
+	gokitLogger := gokitAdapter{logger}
 	webhookServer := http.Server{
 		Handler:           webhookMux,
 		ReadHeaderTimeout: 5 * time.Second}
@@ -85,8 +106,8 @@ func main() {
 	// Run Metrics server
 	{
 		g.Add(func() error {
-			_ = level.Info(logger).Log("msg", "Started external-dns-porkbun-webhook metrics server", "address", metricsListenAddr)
-			return web.ListenAndServe(&metricsServer, &metricsFlags, logger)
+			logger.Info("Started external-dns-porkbun-webhook metrics server", "address", *metricsListenAddr)
+			return web.ListenAndServe(&metricsServer, &metricsFlags, gokitLogger)
 		}, func(error) {
 			ctxShutDown, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 			defer cancel()
@@ -96,8 +117,8 @@ func main() {
 	// Run webhook API server
 	{
 		g.Add(func() error {
-			_ = level.Info(logger).Log("msg", "Started external-dns-porkbun-webhook webhook server", "address", listenAddr)
-			return web.ListenAndServe(&webhookServer, &webhookFlags, logger)
+			logger.Info("Started external-dns-porkbun-webhook webhook server", "address", *listenAddr)
+			return web.ListenAndServe(&webhookServer, &webhookFlags, gokitLogger)
 		}, func(error) {
 			ctxShutDown, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 			defer cancel()
@@ -105,28 +126,55 @@ func main() {
 		})
 	}
 
+	// Run ValidatingAdmissionWebhook server
+	if *admissionListenAddr != "" {
+		admissionMux := buildAdmissionServer(ncProvider, logger)
+		admissionServer := http.Server{
+			Handler:           admissionMux,
+			ReadHeaderTimeout: 5 * time.Second}
+
+		admissionFlags := web.FlagConfig{
+			WebListenAddresses: &[]string{*admissionListenAddr},
+			WebSystemdSocket:   new(bool),
+			WebConfigFile:      tlsConfig,
+		}
+
+		g.Add(func() error {
+			logger.Info("Started external-dns-porkbun-webhook admission server", "address", *admissionListenAddr)
+			return web.ListenAndServe(&admissionServer, &admissionFlags, gokitLogger)
+		}, func(error) {
+			ctxShutDown, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			_ = admissionServer.Shutdown(ctxShutDown)
+		})
+	}
+
 	if err := g.Run(); err != nil {
-		_ = level.Error(logger).Log("msg", "run server group error", "error", err)
+		logger.Error("run server group error", "error", err)
 		os.Exit(1)
 	}
 
 }
 
-func buildMetricsServer(registry prometheus.Gatherer, logger log.Logger) *http.ServeMux {
+func buildMetricsServer(registry prometheus.Gatherer, logger *slog.Logger) (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 
 	var healthzPath = "/healthz"
 	var metricsPath = "/metrics"
 	var rootPath = "/"
 
-	// Add metricsPath
-	mux.Handle(metricsPath, promhttp.HandlerFor(
+	metricsHandler, err := basicAuth(*metricsAuthUser, *metricsAuthPasswordFile, promhttp.HandlerFor(
 		registry,
 		promhttp.HandlerOpts{
 			EnableOpenMetrics: true,
-		}))
+		}).ServeHTTP)
+	if err != nil {
+		return nil, err
+	}
+	mux.HandleFunc(metricsPath, metricsHandler)
 
-	// Add healthzPath
+	// Add healthzPath; always open, even when auth is configured, so
+	// orchestrators can probe liveness without credentials.
 	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(http.StatusText(http.StatusOK)))
@@ -146,35 +194,239 @@ func buildMetricsServer(registry prometheus.Gatherer, logger log.Logger) *http.S
 	}
 	landingPage, err := web.NewLandingPage(landingConfig)
 	if err != nil {
-		_ = level.Error(logger).Log("msg", "failed to create landing page", "error", err)
+		logger.Error("failed to create landing page", "error", err)
 	}
-	mux.Handle(rootPath, landingPage)
+	rootHandler, err := basicAuth(*metricsAuthUser, *metricsAuthPasswordFile, landingPage.ServeHTTP)
+	if err != nil {
+		return nil, err
+	}
+	mux.HandleFunc(rootPath, rootHandler)
 
-	return mux
+	return mux, nil
+}
+
+// buildProvider constructs either a single PorkbunProvider from the
+// porkbun-* flags, or, when --providers-config is set, a MultiProvider that
+// fans requests out across every provider listed in that file.
+func buildProvider(logger *slog.Logger) (extdnsprovider.Provider, error) {
+	if *providersConfig != "" {
+		cfg, err := porkbun.LoadProvidersConfig(*providersConfig)
+		if err != nil {
+			return nil, err
+		}
+		return porkbun.NewMultiProvider(cfg, logger)
+	}
+
+	if len(*domainFilter) == 0 || *apiKey == "" || *apiSecret == "" {
+		return nil, fmt.Errorf("domain-filter, porkbun-api-key, and porkbun-secret-key are required when --providers-config is not set")
+	}
+
+	return porkbun.NewPorkbunProvider(*domainFilter, *apiKey, *apiSecret, *dryRun, logger)
+}
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "porkbun_webhook_request_total",
+		Help: "Total number of webhook HTTP requests, by path and status code.",
+	}, []string{"path", "code"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "porkbun_webhook_request_duration_seconds",
+		Help:    "Latency of webhook HTTP requests, by path.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"path"})
+	requestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "porkbun_webhook_requests_in_flight",
+		Help: "Number of webhook HTTP requests currently being served, by path.",
+	}, []string{"path"})
+)
+
+// instrument wraps handler with the standard promhttp request-total,
+// duration, and in-flight metrics, labelled with path.
+func instrument(path string, handler http.HandlerFunc) http.HandlerFunc {
+	return promhttp.InstrumentHandlerInFlight(
+		requestsInFlight.WithLabelValues(path),
+		promhttp.InstrumentHandlerDuration(
+			requestDuration.MustCurryWith(prometheus.Labels{"path": path}),
+			promhttp.InstrumentHandlerCounter(
+				requestsTotal.MustCurryWith(prometheus.Labels{"path": path}),
+				handler,
+			),
+		),
+	).ServeHTTP
+}
+
+// withRequestLogger attaches a logger carrying method/path/remote_addr
+// fields to the request context, so the provider can log with them without
+// threading a logger through every call.
+func withRequestLogger(logger *slog.Logger, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestLogger := logger.With("method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+		ctx := porkbun.ContextWithLogger(r.Context(), requestLogger)
+		handler(w, r.WithContext(ctx))
+	}
 }
 
-func buildWebhookServer(logger log.Logger) (*http.ServeMux, error) {
+func buildWebhookServer(logger *slog.Logger) (*http.ServeMux, extdnsprovider.Provider, error) {
 	mux := http.NewServeMux()
 
 	var rootPath = "/"
 	var recordsPath = "/records"
 	var adjustEndpointsPath = "/adjustendpoints"
 
-	ncProvider, err := porkbun.NewPorkbunProvider(domainFilter, *apiKey, *apiSecret, *dryRun, logger)
+	ncProvider, err := buildProvider(logger)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	p := webhook.WebhookServer{
 		Provider: ncProvider,
 	}
 
+	prometheus.DefaultRegisterer.MustRegister(requestsTotal, requestDuration, requestsInFlight)
+
+	negotiateHandler, err := basicAuth(*webhookAuthUser, *webhookAuthPasswordFile, p.NegotiateHandler)
+	if err != nil {
+		return nil, nil, err
+	}
+	adjustEndpointsHandler, err := basicAuth(*webhookAuthUser, *webhookAuthPasswordFile, p.AdjustEndpointsHandler)
+	if err != nil {
+		return nil, nil, err
+	}
+	recordsHandler, err := basicAuth(*webhookAuthUser, *webhookAuthPasswordFile, p.RecordsHandler)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Add negotiatePath
-	mux.HandleFunc(rootPath, p.NegotiateHandler)
+	mux.HandleFunc(rootPath, instrument(rootPath, withRequestLogger(logger, negotiateHandler)))
 	// Add adjustEndpointsPath
-	mux.HandleFunc(adjustEndpointsPath, p.AdjustEndpointsHandler)
+	mux.HandleFunc(adjustEndpointsPath, instrument(adjustEndpointsPath, withRequestLogger(logger, adjustEndpointsHandler)))
 	// Add recordsPath
-	mux.HandleFunc(recordsPath, p.RecordsHandler)
+	mux.HandleFunc(recordsPath, instrument(recordsPath, withRequestLogger(logger, recordsHandler)))
 
-	return mux, nil
+	return mux, ncProvider, nil
+}
+
+// buildAdmissionServer returns a mux serving a ValidatingAdmissionWebhook
+// that checks DNSEndpoint objects against provider before external-dns
+// applies them.
+func buildAdmissionServer(provider extdnsprovider.Provider, logger *slog.Logger) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	validator := &admission.Validator{
+		Provider:     provider,
+		DomainFilter: provider.GetDomainFilter(),
+		Logger:       logger,
+	}
+
+	mux.HandleFunc("/validate", validator.Handler())
+
+	return mux
 }
+
+// basicAuth wraps handler so it requires HTTP basic auth matching user and
+// the bcrypt-hashed password stored in passwordFile. When user is empty,
+// auth is disabled and handler is returned unwrapped.
+func basicAuth(user, passwordFile string, handler http.HandlerFunc) (http.HandlerFunc, error) {
+	if user == "" {
+		return handler, nil
+	}
+
+	hash, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading auth password file %s: %w", passwordFile, err)
+	}
+	hash = bytes.TrimSpace(hash)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, ok := r.BasicAuth()
+		if !ok || gotUser != user || bcrypt.CompareHashAndPassword(hash, []byte(gotPass)) != nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="porkbun-webhook"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}, nil
+}
+
+// parseLogLevel maps the --log-level flag's values to a slog.Level.
+func parseLogLevel(s string) slog.Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug
+	case "warning", "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// dedupHandler suppresses an immediately-repeated identical log record,
+// which is common when external-dns polls Records() every minute and
+// nothing has changed. The last-seen key is held in dedupState, shared by
+// pointer across every handler WithAttrs/WithGroup derives from this one,
+// so per-request child loggers (see withRequestLogger) still dedup against
+// each other instead of each starting with a blank slate.
+type dedupHandler struct {
+	slog.Handler
+
+	state *dedupState
+}
+
+type dedupState struct {
+	mu      sync.Mutex
+	lastKey string
+	lastAt  time.Time
+}
+
+// dedupWindow is comfortably longer than external-dns's default one-minute
+// poll interval, so two identical log lines a poll cycle apart still dedup.
+const dedupWindow = 90 * time.Second
+
+func newDedupHandler(h slog.Handler) *dedupHandler {
+	return &dedupHandler{Handler: h, state: &dedupState{}}
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	var key strings.Builder
+	key.WriteString(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(&key, "|%s=%v", a.Key, a.Value)
+		return true
+	})
+
+	h.state.mu.Lock()
+	repeat := key.String() == h.state.lastKey && time.Since(h.state.lastAt) < dedupWindow
+	h.state.lastKey = key.String()
+	h.state.lastAt = time.Now()
+	h.state.mu.Unlock()
+
+	if repeat {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dedupHandler{Handler: h.Handler.WithAttrs(attrs), state: h.state}
+}
+
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	return &dedupHandler{Handler: h.Handler.WithGroup(name), state: h.state}
+}
+
+// gokitAdapter satisfies go-kit/log's Logger interface by forwarding to a
+// slog.Logger, since github.com/prometheus/exporter-toolkit/web still
+// expects one.
+type gokitAdapter struct {
+	logger *slog.Logger
+}
+
+func (a gokitAdapter) Log(keyvals ...any) error {
+	a.logger.Info("", keyvals...)
+	return nil
+}
+
+var _ gokitlog.Logger = gokitAdapter{}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuth_disabledWhenUserEmpty(t *testing.T) {
+	called := false
+	handler, err := basicAuth("", "", func(w http.ResponseWriter, r *http.Request) { called = true })
+	if err != nil {
+		t.Fatalf("basicAuth: %v", err)
+	}
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called {
+		t.Fatal("expected the wrapped handler to run when auth is disabled")
+	}
+}
+
+func writeHashFile(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt.GenerateFromPassword: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "password-hash")
+	if err := os.WriteFile(path, hash, 0o600); err != nil {
+		t.Fatalf("writing password hash file: %v", err)
+	}
+	return path
+}
+
+func TestBasicAuth_rejectsMissingOrWrongCredentials(t *testing.T) {
+	tests := []struct {
+		name       string
+		setHeader  bool
+		user, pass string
+	}{
+		{name: "no credentials"},
+		{name: "wrong username", setHeader: true, user: "someone-else", pass: "correct-horse"},
+		{name: "wrong password", setHeader: true, user: "admin", pass: "wrong-password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := basicAuth("admin", writeHashFile(t, "correct-horse"), func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("wrapped handler must not run for unauthenticated requests")
+			})
+			if err != nil {
+				t.Fatalf("basicAuth: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.setHeader {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestBasicAuth_acceptsCorrectCredentials(t *testing.T) {
+	called := false
+	handler, err := basicAuth("admin", writeHashFile(t, "correct-horse"), func(w http.ResponseWriter, r *http.Request) { called = true })
+	if err != nil {
+		t.Fatalf("basicAuth: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("admin", "correct-horse")
+	handler(httptest.NewRecorder(), req)
+	if !called {
+		t.Fatal("expected the wrapped handler to run for correct credentials")
+	}
+}
+
+// countingHandler counts how many records reach it, so tests can tell
+// whether dedupHandler suppressed a repeat.
+type countingHandler struct {
+	slog.Handler
+	count *int
+}
+
+func (h countingHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.count++
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return countingHandler{Handler: h.Handler.WithAttrs(attrs), count: h.count}
+}
+
+func (h countingHandler) WithGroup(name string) slog.Handler {
+	return countingHandler{Handler: h.Handler.WithGroup(name), count: h.count}
+}
+
+func TestDedupHandler_suppressesImmediateRepeat(t *testing.T) {
+	var count int
+	h := newDedupHandler(countingHandler{Handler: slog.NewTextHandler(os.Stderr, nil), count: &count})
+	logger := slog.New(h)
+
+	logger.Info("retrieving records", "zone", "example.com")
+	logger.Info("retrieving records", "zone", "example.com")
+
+	if count != 1 {
+		t.Fatalf("got %d records handled, want 1 (second should be deduped)", count)
+	}
+}
+
+func TestDedupHandler_sharedAcrossWithAttrs(t *testing.T) {
+	var count int
+	h := newDedupHandler(countingHandler{Handler: slog.NewTextHandler(os.Stderr, nil), count: &count})
+	root := slog.New(h)
+
+	// withRequestLogger derives a per-request logger via With() on every
+	// request; the dedup state must be shared across those children, not
+	// reset per request.
+	first := root.With("remote_addr", "10.0.0.1:1111")
+	second := root.With("remote_addr", "10.0.0.2:2222")
+
+	first.Info("retrieving records", "zone", "example.com")
+	second.Info("retrieving records", "zone", "example.com")
+
+	if count != 1 {
+		t.Fatalf("got %d records handled, want 1 (repeat across derived handlers should be deduped)", count)
+	}
+}
+
+func TestDedupHandler_suppressesRepeatAcrossPollInterval(t *testing.T) {
+	var count int
+	h := newDedupHandler(countingHandler{Handler: slog.NewTextHandler(os.Stderr, nil), count: &count})
+	logger := slog.New(h)
+
+	logger.Info("retrieving records", "zone", "example.com")
+
+	// Simulate external-dns's ~60s poll interval elapsing without actually
+	// sleeping the test.
+	h.state.lastAt = h.state.lastAt.Add(-60 * time.Second)
+
+	logger.Info("retrieving records", "zone", "example.com")
+
+	if count != 1 {
+		t.Fatalf("got %d records handled, want 1 (a repeat ~60s later, within a poll interval, should still be deduped)", count)
+	}
+}
+
+func TestDedupHandler_allowsDistinctMessages(t *testing.T) {
+	var count int
+	h := newDedupHandler(countingHandler{Handler: slog.NewTextHandler(os.Stderr, nil), count: &count})
+	logger := slog.New(h)
+
+	logger.Info("retrieving records", "zone", "example.com")
+	logger.Info("retrieving records", "zone", "example.net")
+
+	if count != 2 {
+		t.Fatalf("got %d records handled, want 2 (different zones should not dedup)", count)
+	}
+}
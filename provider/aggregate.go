@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// Factory builds a provider.Provider from a ProviderConfig entry. Provider
+// types register a Factory with RegisterProviderType so MultiProvider can
+// construct them from a providers-config file without knowing their
+// concrete type.
+type Factory func(cfg ProviderConfig, logger *slog.Logger) (provider.Provider, error)
+
+var registry = map[string]Factory{
+	"porkbun": newPorkbunFromConfig,
+}
+
+// RegisterProviderType makes a new provider type available to
+// providers-config files under the given name. It is intended to be called
+// from an init() in the package implementing that provider type.
+func RegisterProviderType(name string, factory Factory) {
+	registry[name] = factory
+}
+
+func newPorkbunFromConfig(cfg ProviderConfig, logger *slog.Logger) (provider.Provider, error) {
+	return NewPorkbunProvider(cfg.DomainFilter, cfg.Credentials["apiKey"], cfg.Credentials["apiSecret"], cfg.DryRun, logger)
+}
+
+// member pairs a constructed provider with the domain filter it owns, so
+// MultiProvider can route each endpoint to the provider that manages it.
+type member struct {
+	domainFilter endpoint.DomainFilter
+	provider     provider.Provider
+}
+
+// MultiProvider fans Records/ApplyChanges calls out across several upstream
+// providers, each scoped to its own domain filter, so a single webhook
+// deployment can front more than one registrar or account.
+type MultiProvider struct {
+	provider.BaseProvider
+
+	domainFilter endpoint.DomainFilter
+	members      []member
+	logger       *slog.Logger
+}
+
+// NewMultiProvider constructs a MultiProvider from a ProvidersConfig,
+// instantiating each entry via its registered Factory.
+func NewMultiProvider(cfg *ProvidersConfig, logger *slog.Logger) (*MultiProvider, error) {
+	mp := &MultiProvider{logger: logger}
+
+	var allZones []string
+	for _, pc := range cfg.Providers {
+		factory, ok := registry[pc.Type]
+		if !ok {
+			return nil, fmt.Errorf("multiprovider: unknown provider type %q", pc.Type)
+		}
+
+		p, err := factory(pc, logger)
+		if err != nil {
+			return nil, fmt.Errorf("multiprovider: constructing %q provider: %w", pc.Type, err)
+		}
+
+		mp.members = append(mp.members, member{
+			domainFilter: endpoint.NewDomainFilter(pc.DomainFilter),
+			provider:     p,
+		})
+		allZones = append(allZones, pc.DomainFilter...)
+	}
+
+	mp.domainFilter = endpoint.NewDomainFilter(allZones)
+
+	return mp, nil
+}
+
+// GetDomainFilter returns the union of every member provider's domain
+// filter.
+func (mp *MultiProvider) GetDomainFilter() endpoint.DomainFilterInterface {
+	return mp.domainFilter
+}
+
+// Records merges the records reported by every member provider.
+func (mp *MultiProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	var merged []*endpoint.Endpoint
+
+	for _, m := range mp.members {
+		records, err := m.provider.Records(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("multiprovider: records from %s: %w", m.domainFilter, err)
+		}
+		merged = append(merged, records...)
+	}
+
+	return merged, nil
+}
+
+// ApplyChanges splits changes by the DNSName of each endpoint and dispatches
+// each slice to the owning member provider.
+func (mp *MultiProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	perMember := make([]*plan.Changes, len(mp.members))
+	for i := range perMember {
+		perMember[i] = &plan.Changes{}
+	}
+
+	split := func(endpoints []*endpoint.Endpoint, pick func(*plan.Changes) *[]*endpoint.Endpoint) error {
+		for _, ep := range endpoints {
+			idx, ok := mp.ownerOf(ep.DNSName)
+			if !ok {
+				return fmt.Errorf("multiprovider: no provider owns zone for %s", ep.DNSName)
+			}
+			dst := pick(perMember[idx])
+			*dst = append(*dst, ep)
+		}
+		return nil
+	}
+
+	if err := split(changes.Create, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.Create }); err != nil {
+		return err
+	}
+	if err := split(changes.UpdateOld, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.UpdateOld }); err != nil {
+		return err
+	}
+	if err := split(changes.UpdateNew, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.UpdateNew }); err != nil {
+		return err
+	}
+	if err := split(changes.Delete, func(c *plan.Changes) *[]*endpoint.Endpoint { return &c.Delete }); err != nil {
+		return err
+	}
+
+	for i, m := range mp.members {
+		if err := m.provider.ApplyChanges(ctx, perMember[i]); err != nil {
+			return fmt.Errorf("multiprovider: applying changes to %s: %w", m.domainFilter, err)
+		}
+	}
+
+	return nil
+}
+
+// ownerOf returns the index of the member provider whose domain filter
+// matches dnsName.
+func (mp *MultiProvider) ownerOf(dnsName string) (int, bool) {
+	for i, m := range mp.members {
+		if m.domainFilter.Match(dnsName) {
+			return i, true
+		}
+	}
+	LoggerFromContext(context.Background(), mp.logger).Debug("no provider matched zone", "name", dnsName)
+	return 0, false
+}
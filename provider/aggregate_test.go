@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	extdnsprovider "sigs.k8s.io/external-dns/provider"
+)
+
+// fakeMemberProvider is a minimal provider.Provider double used to observe
+// what MultiProvider dispatches to each member.
+type fakeMemberProvider struct {
+	extdnsprovider.BaseProvider
+
+	applied *plan.Changes
+}
+
+func (p *fakeMemberProvider) GetDomainFilter() endpoint.DomainFilterInterface {
+	return endpoint.NewDomainFilter(nil)
+}
+
+func (p *fakeMemberProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return nil, nil
+}
+
+func (p *fakeMemberProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.applied = changes
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestMultiProvider_ownerOf(t *testing.T) {
+	mp := &MultiProvider{
+		members: []member{
+			{domainFilter: endpoint.NewDomainFilter([]string{"example.com"}), provider: &fakeMemberProvider{}},
+			{domainFilter: endpoint.NewDomainFilter([]string{"example.net"}), provider: &fakeMemberProvider{}},
+		},
+		logger: testLogger(),
+	}
+
+	tests := []struct {
+		name    string
+		dnsName string
+		wantIdx int
+		wantOk  bool
+	}{
+		{"matches first member", "foo.example.com", 0, true},
+		{"matches second member", "foo.example.net", 1, true},
+		{"matches no member", "foo.example.org", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, ok := mp.ownerOf(tt.dnsName)
+			if ok != tt.wantOk {
+				t.Fatalf("ownerOf(%q) ok = %v, want %v", tt.dnsName, ok, tt.wantOk)
+			}
+			if ok && idx != tt.wantIdx {
+				t.Fatalf("ownerOf(%q) = %d, want %d", tt.dnsName, idx, tt.wantIdx)
+			}
+		})
+	}
+}
+
+func TestMultiProvider_ApplyChanges_splitsByOwner(t *testing.T) {
+	a := &fakeMemberProvider{}
+	b := &fakeMemberProvider{}
+	mp := &MultiProvider{
+		members: []member{
+			{domainFilter: endpoint.NewDomainFilter([]string{"example.com"}), provider: a},
+			{domainFilter: endpoint.NewDomainFilter([]string{"example.net"}), provider: b},
+		},
+		logger: testLogger(),
+	}
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("foo.example.com", "A", "1.2.3.4"),
+			endpoint.NewEndpoint("bar.example.net", "A", "5.6.7.8"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("baz.example.com", "A", "9.9.9.9"),
+		},
+	}
+
+	if err := mp.ApplyChanges(context.Background(), changes); err != nil {
+		t.Fatalf("ApplyChanges: %v", err)
+	}
+
+	if len(a.applied.Create) != 1 || a.applied.Create[0].DNSName != "foo.example.com" {
+		t.Fatalf("provider a got unexpected create changes: %+v", a.applied.Create)
+	}
+	if len(a.applied.Delete) != 1 || a.applied.Delete[0].DNSName != "baz.example.com" {
+		t.Fatalf("provider a got unexpected delete changes: %+v", a.applied.Delete)
+	}
+	if len(b.applied.Create) != 1 || b.applied.Create[0].DNSName != "bar.example.net" {
+		t.Fatalf("provider b got unexpected create changes: %+v", b.applied.Create)
+	}
+	if len(b.applied.Delete) != 0 {
+		t.Fatalf("provider b got unexpected delete changes: %+v", b.applied.Delete)
+	}
+}
+
+func TestMultiProvider_ApplyChanges_unownedZoneErrors(t *testing.T) {
+	mp := &MultiProvider{
+		members: []member{
+			{domainFilter: endpoint.NewDomainFilter([]string{"example.com"}), provider: &fakeMemberProvider{}},
+		},
+		logger: testLogger(),
+	}
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", "A", "1.2.3.4")},
+	}
+
+	if err := mp.ApplyChanges(context.Background(), changes); err == nil {
+		t.Fatal("expected an error for an endpoint with no owning provider")
+	}
+}
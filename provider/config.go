@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProvidersConfig is the top-level shape of the file passed via
+// --providers-config. It describes one upstream provider per entry; each
+// entry's DomainFilter determines which DNSEndpoints it owns.
+type ProvidersConfig struct {
+	Providers []ProviderConfig `yaml:"providers" json:"providers"`
+}
+
+// ProviderConfig describes a single upstream registrar/provider entry in a
+// ProvidersConfig file.
+type ProviderConfig struct {
+	// Type selects the Factory registered for this provider, e.g. "porkbun".
+	Type string `yaml:"type" json:"type"`
+	// DomainFilter is the set of zones this provider owns.
+	DomainFilter []string `yaml:"domainFilter" json:"domainFilter"`
+	// DryRun disables mutating calls for this provider only.
+	DryRun bool `yaml:"dryRun" json:"dryRun"`
+	// Credentials is passed verbatim to the Factory registered for Type, so
+	// each provider type can define its own credential shape.
+	Credentials map[string]string `yaml:"credentials" json:"credentials"`
+}
+
+// LoadProvidersConfig reads and parses a ProvidersConfig from path. YAML and
+// JSON are both accepted, since JSON is a subset of YAML.
+func LoadProvidersConfig(path string) (*ProvidersConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading providers config: %w", err)
+	}
+
+	var cfg ProvidersConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing providers config: %w", err)
+	}
+
+	if len(cfg.Providers) == 0 {
+		return nil, fmt.Errorf("providers config %s defines no providers", path)
+	}
+
+	return &cfg, nil
+}
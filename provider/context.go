@@ -0,0 +1,26 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKeyType struct{}
+
+var loggerKey = loggerKeyType{}
+
+// ContextWithLogger returns a copy of ctx carrying logger, retrievable with
+// LoggerFromContext. The webhook handlers use this to attach request-scoped
+// fields (method, path, remote_addr) before calling into the provider.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// LoggerFromContext returns the logger attached to ctx by ContextWithLogger,
+// or fallback if ctx carries none.
+func LoggerFromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return fallback
+}
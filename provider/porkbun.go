@@ -0,0 +1,260 @@
+// Package provider implements an external-dns webhook provider backed by
+// the Porkbun DNS API (https://porkbun.com/api/json/v3/documentation).
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	apiBaseURL     = "https://api.porkbun.com/api/json/v3"
+	apiHTTPTimeout = 30 * time.Second
+)
+
+var (
+	apiCallsTotal = promauto.With(prometheus.DefaultRegisterer).NewCounterVec(prometheus.CounterOpts{
+		Name: "porkbun_api_calls_total",
+		Help: "Total number of calls made to the Porkbun API, by operation and result.",
+	}, []string{"operation", "result"})
+	apiCallDuration = promauto.With(prometheus.DefaultRegisterer).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "porkbun_api_call_duration_seconds",
+		Help:    "Latency of calls made to the Porkbun API, by operation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+// PorkbunProvider is an external-dns provider.Provider backed by a single
+// Porkbun account.
+type PorkbunProvider struct {
+	provider.BaseProvider
+
+	domainFilter endpoint.DomainFilter
+	apiKey       string
+	apiSecret    string
+	dryRun       bool
+	logger       *slog.Logger
+
+	// baseURL is apiBaseURL in production; tests point it at an
+	// httptest.Server instead.
+	baseURL string
+	client  *http.Client
+}
+
+// NewPorkbunProvider constructs a PorkbunProvider scoped to domainFilter and
+// authenticated with the given Porkbun API credentials.
+func NewPorkbunProvider(domainFilter []string, apiKey, apiSecret string, dryRun bool, logger *slog.Logger) (provider.Provider, error) {
+	if apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("porkbun: api key and secret are required")
+	}
+
+	return &PorkbunProvider{
+		domainFilter: endpoint.NewDomainFilter(domainFilter),
+		apiKey:       apiKey,
+		apiSecret:    apiSecret,
+		dryRun:       dryRun,
+		logger:       logger,
+		baseURL:      apiBaseURL,
+		client:       &http.Client{Timeout: apiHTTPTimeout},
+	}, nil
+}
+
+// GetDomainFilter returns the zones this provider is permitted to manage.
+func (p *PorkbunProvider) GetDomainFilter() endpoint.DomainFilterInterface {
+	return p.domainFilter
+}
+
+// Records returns all DNS records Porkbun holds for the managed zones.
+func (p *PorkbunProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	var endpoints []*endpoint.Endpoint
+
+	for _, zone := range p.domainFilter.Filters {
+		records, err := p.retrieveRecords(ctx, zone)
+		if err != nil {
+			return nil, fmt.Errorf("porkbun: retrieving records for %s: %w", zone, err)
+		}
+		endpoints = append(endpoints, records...)
+	}
+
+	return endpoints, nil
+}
+
+// ApplyChanges creates, updates, and deletes records on Porkbun to realize
+// the given plan.Changes.
+func (p *PorkbunProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	for _, ep := range changes.Delete {
+		if err := p.deleteRecord(ctx, ep); err != nil {
+			return fmt.Errorf("porkbun: deleting %s: %w", ep.DNSName, err)
+		}
+	}
+
+	for _, ep := range changes.UpdateOld {
+		if err := p.deleteRecord(ctx, ep); err != nil {
+			return fmt.Errorf("porkbun: deleting stale %s before update: %w", ep.DNSName, err)
+		}
+	}
+
+	for _, ep := range append(changes.Create, changes.UpdateNew...) {
+		if err := p.createRecord(ctx, ep); err != nil {
+			return fmt.Errorf("porkbun: creating %s: %w", ep.DNSName, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PorkbunProvider) retrieveRecords(ctx context.Context, zone string) ([]*endpoint.Endpoint, error) {
+	LoggerFromContext(ctx, p.logger).Debug("retrieving records", "zone", zone)
+
+	var resp struct {
+		Records []struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			Content string `json:"content"`
+			TTL     string `json:"ttl"`
+		} `json:"records"`
+	}
+
+	if err := p.call(ctx, "retrieve", fmt.Sprintf("/dns/retrieve/%s", zone), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(resp.Records))
+	for _, rec := range resp.Records {
+		endpoints = append(endpoints, endpoint.NewEndpoint(rec.Name, rec.Type, rec.Content))
+	}
+
+	return endpoints, nil
+}
+
+func (p *PorkbunProvider) createRecord(ctx context.Context, ep *endpoint.Endpoint) error {
+	zone, name, ok := p.splitZone(ep.DNSName)
+	if !ok {
+		return fmt.Errorf("no managed zone matches %s", ep.DNSName)
+	}
+
+	if p.dryRun {
+		LoggerFromContext(ctx, p.logger).Info("dry-run: skipping record creation", "zone", zone, "record_id", name, "type", ep.RecordType)
+		return nil
+	}
+
+	body := map[string]string{
+		"name":    name,
+		"type":    ep.RecordType,
+		"content": ep.Targets[0],
+	}
+
+	return p.call(ctx, "create", fmt.Sprintf("/dns/create/%s", zone), body, nil)
+}
+
+func (p *PorkbunProvider) deleteRecord(ctx context.Context, ep *endpoint.Endpoint) error {
+	zone, name, ok := p.splitZone(ep.DNSName)
+	if !ok {
+		return fmt.Errorf("no managed zone matches %s", ep.DNSName)
+	}
+
+	if p.dryRun {
+		LoggerFromContext(ctx, p.logger).Info("dry-run: skipping record deletion", "zone", zone, "record_id", name, "type", ep.RecordType)
+		return nil
+	}
+
+	return p.call(ctx, "delete", fmt.Sprintf("/dns/deleteByNameType/%s/%s/%s", zone, ep.RecordType, name), nil, nil)
+}
+
+// splitZone finds the managed zone that owns dnsName and returns the zone
+// and the record name relative to it.
+func (p *PorkbunProvider) splitZone(dnsName string) (zone, name string, ok bool) {
+	for _, z := range p.domainFilter.Filters {
+		if dnsName == z {
+			return z, "", true
+		}
+		if strings.HasSuffix(dnsName, "."+z) {
+			return z, strings.TrimSuffix(dnsName, "."+z), true
+		}
+	}
+	return "", "", false
+}
+
+// call issues a Porkbun API request and records porkbun_api_calls_total and
+// porkbun_api_call_duration_seconds for it, labelled by operation.
+func (p *PorkbunProvider) call(ctx context.Context, operation, path string, body map[string]string, out any) (err error) {
+	start := time.Now()
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		apiCallsTotal.WithLabelValues(operation, result).Inc()
+		apiCallDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}()
+
+	if body == nil {
+		body = map[string]string{}
+	}
+	body["apikey"] = p.apiKey
+	body["secretapikey"] = p.apiSecret
+
+	payload, marshalErr := json.Marshal(body)
+	if marshalErr != nil {
+		err = marshalErr
+		return err
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(payload))
+	if reqErr != nil {
+		err = reqErr
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, doErr := p.client.Do(req)
+	if doErr != nil {
+		err = doErr
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status %s from %s", resp.Status, path)
+		return err
+	}
+
+	respBody, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		err = readErr
+		return err
+	}
+
+	var envelope struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if unmarshalErr := json.Unmarshal(respBody, &envelope); unmarshalErr != nil {
+		err = unmarshalErr
+		return err
+	}
+	if envelope.Status != "SUCCESS" {
+		err = fmt.Errorf("porkbun api: %s: %s", path, envelope.Message)
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(respBody, out)
+	return err
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestPorkbunProvider_splitZone(t *testing.T) {
+	p := &PorkbunProvider{domainFilter: endpoint.NewDomainFilter([]string{"example.com", "example.net"})}
+
+	tests := []struct {
+		name     string
+		dnsName  string
+		wantZone string
+		wantName string
+		wantOk   bool
+	}{
+		{"apex record", "example.com", "example.com", "", true},
+		{"subdomain", "www.example.com", "example.com", "www", true},
+		{"other managed zone", "example.net", "example.net", "", true},
+		{"unmanaged zone", "www.example.org", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, name, ok := p.splitZone(tt.dnsName)
+			if ok != tt.wantOk || zone != tt.wantZone || name != tt.wantName {
+				t.Fatalf("splitZone(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.dnsName, zone, name, ok, tt.wantZone, tt.wantName, tt.wantOk)
+			}
+		})
+	}
+}
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *PorkbunProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &PorkbunProvider{
+		domainFilter: endpoint.NewDomainFilter([]string{"example.com"}),
+		apiKey:       "key",
+		apiSecret:    "secret",
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		baseURL:      server.URL,
+		client:       server.Client(),
+	}
+}
+
+func TestPorkbunProvider_call_apiLevelError(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "ERROR",
+			"message": "Invalid API key.",
+		})
+	})
+
+	if err := p.call(context.Background(), "create", "/dns/create/example.com", map[string]string{}, nil); err == nil {
+		t.Fatal("expected an error for a status:ERROR response, got nil")
+	}
+}
+
+func TestPorkbunProvider_retrieveRecords_apiLevelError(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "ERROR",
+			"message": "Domain not found.",
+		})
+	})
+
+	if _, err := p.retrieveRecords(context.Background(), "example.com"); err == nil {
+		t.Fatal("expected an error for a status:ERROR records response, got nil")
+	}
+}
+
+func TestPorkbunProvider_retrieveRecords_success(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]any{
+			"status": "SUCCESS",
+			"records": []map[string]string{
+				{"name": "www.example.com", "type": "A", "content": "1.2.3.4", "ttl": "600"},
+			},
+		})
+	})
+
+	records, err := p.retrieveRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("retrieveRecords: %v", err)
+	}
+	if len(records) != 1 || records[0].DNSName != "www.example.com" {
+		t.Fatalf("retrieveRecords = %+v, want one record for www.example.com", records)
+	}
+}
+
+func TestPorkbunProvider_createRecord_success(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "SUCCESS"})
+	})
+
+	ep := endpoint.NewEndpoint("www.example.com", "A", "1.2.3.4")
+	if err := p.createRecord(context.Background(), ep); err != nil {
+		t.Fatalf("createRecord: %v", err)
+	}
+}